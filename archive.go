@@ -0,0 +1,161 @@
+package pcd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// ArchiveFormat selects the container format used by Podcast.Archive.
+type ArchiveFormat int
+
+const (
+	ArchiveFormatZip ArchiveFormat = iota
+	ArchiveFormatTarGz
+)
+
+// archiveManifest is written into the archive as "feed.json" alongside the
+// episode files, so the exported archive is self-describing.
+type archiveManifest struct {
+	ID       int       `json:"id"`
+	Name     string    `json:"name"`
+	Feed     string    `json:"feed"`
+	Episodes []Episode `json:"episodes"`
+}
+
+// Archive streams a zip or tar.gz of the episodes in p that are already
+// downloaded to p.Path, plus a "feed.json" manifest describing the podcast
+// and the archived episodes. filter, if non-nil, restricts which episodes
+// are included (e.g. only the last 10, or a date range); episodes whose
+// file isn't present on disk are skipped regardless of filter.
+func (p *Podcast) Archive(w io.Writer, format ArchiveFormat, filter func(Episode) bool) error {
+	var episodes []Episode
+	for _, episode := range p.Episodes {
+		if filter != nil && !filter(episode) {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(p.Path, filenameForURL(episode.URL))); err != nil {
+			continue
+		}
+		episodes = append(episodes, episode)
+	}
+
+	manifest, err := json.MarshalIndent(archiveManifest{
+		ID:       p.ID,
+		Name:     p.Name,
+		Feed:     p.Feed,
+		Episodes: episodes,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case ArchiveFormatZip:
+		return archiveZip(w, p.Path, episodes, manifest)
+	case ArchiveFormatTarGz:
+		return archiveTarGz(w, p.Path, episodes, manifest)
+	default:
+		return errors.Errorf("unknown archive format %d", format)
+	}
+}
+
+func archiveZip(w io.Writer, root string, episodes []Episode, manifest []byte) (err error) {
+	zw := zip.NewWriter(w)
+	defer func() {
+		if cerr := zw.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	mf, err := zw.CreateHeader(&zip.FileHeader{Name: "feed.json", Method: zip.Deflate})
+	if err != nil {
+		return err
+	}
+	if _, err := mf.Write(manifest); err != nil {
+		return err
+	}
+
+	for _, episode := range episodes {
+		filename := filenameForURL(episode.URL)
+		if err := copyFileToZip(zw, filepath.Join(root, filename), filename); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func copyFileToZip(zw *zip.Writer, path, name string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fw, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(fw, f)
+	return err
+}
+
+func archiveTarGz(w io.Writer, root string, episodes []Episode, manifest []byte) (err error) {
+	gz := gzip.NewWriter(w)
+	defer func() {
+		if cerr := gz.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	tw := tar.NewWriter(gz)
+	defer func() {
+		if cerr := tw.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	if err := tw.WriteHeader(&tar.Header{Name: "feed.json", Size: int64(len(manifest)), Mode: 0644}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(manifest); err != nil {
+		return err
+	}
+
+	for _, episode := range episodes {
+		filename := filenameForURL(episode.URL)
+		if err := copyFileToTar(tw, filepath.Join(root, filename), filename); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func copyFileToTar(tw *tar.Writer, path, name string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	if err := tw.WriteHeader(&tar.Header{Name: name, Size: info.Size(), Mode: 0644}); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(tw, f)
+	return err
+}
@@ -0,0 +1,115 @@
+package pcd
+
+import (
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// feedMagic identifies the versioned ".feed" cache format introduced by
+// encodeFeed/decodeFeed, as opposed to the legacy gob+base64 format.
+var feedMagic = [4]byte{'P', 'C', 'D', 'F'}
+
+// currentFeedVersion is bumped whenever a field is added to feedPayload,
+// so future readers can migrate older caches instead of discarding them.
+//
+// v2 added ETag/LastModified so Client.Sync can make conditional requests.
+const currentFeedVersion uint16 = 2
+
+const feedCodecGZIPJSON uint8 = 1
+
+// feedHeader is the fixed-size header written at the start of every
+// versioned ".feed" cache file.
+type feedHeader struct {
+	Magic   [4]byte
+	Version uint16
+	Codec   uint8
+}
+
+// feedPayload is the gzip-compressed JSON body that follows feedHeader.
+type feedPayload struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	Feed         string    `json:"feed"`
+	Episodes     []Episode `json:"episodes"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+}
+
+// encodeFeed writes p to w using the versioned ".feed" cache format: a
+// fixed-size feedHeader followed by a gzip-compressed JSON payload of the
+// podcast metadata and episodes.
+func encodeFeed(w io.Writer, p *Podcast) (err error) {
+	header := feedHeader{Magic: feedMagic, Version: currentFeedVersion, Codec: feedCodecGZIPJSON}
+	if err := binary.Write(w, binary.BigEndian, header); err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(w)
+	defer func() {
+		if cerr := gz.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	payload := feedPayload{
+		ID:           p.ID,
+		Name:         p.Name,
+		Feed:         p.Feed,
+		Episodes:     p.Episodes,
+		ETag:         p.ETag,
+		LastModified: p.LastModified,
+	}
+
+	return json.NewEncoder(gz).Encode(payload)
+}
+
+// decodeFeed reads a versioned ".feed" cache file written by encodeFeed.
+// Callers should sniff the leading feedMagic bytes first to tell this
+// format apart from the legacy gob+base64 one (see fromGOB64).
+//
+// header.Version is validated against the versions this build knows how to
+// read. Versions 1 and 2 share the same feedPayload JSON schema (v2 only
+// added the optional ETag/LastModified fields, which JSON decodes as zero
+// values when absent), so both are accepted and decoded identically today.
+// A cache written by a newer, potentially incompatible version is rejected
+// instead of being silently mis-decoded; a future breaking change to
+// feedPayload should add a case here rather than just bumping the const.
+func decodeFeed(r io.Reader) (*Podcast, error) {
+	var header feedHeader
+	if err := binary.Read(r, binary.BigEndian, &header); err != nil {
+		return nil, err
+	}
+	if header.Magic != feedMagic {
+		return nil, errors.New("not a pcd feed cache")
+	}
+	if header.Codec != feedCodecGZIPJSON {
+		return nil, errors.Errorf("unsupported feed cache codec %d", header.Codec)
+	}
+	if header.Version < 1 || header.Version > currentFeedVersion {
+		return nil, errors.Errorf("unsupported feed cache version %d", header.Version)
+	}
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	var payload feedPayload
+	if err := json.NewDecoder(gz).Decode(&payload); err != nil {
+		return nil, err
+	}
+
+	return &Podcast{
+		ID:           payload.ID,
+		Name:         payload.Name,
+		Feed:         payload.Feed,
+		Episodes:     payload.Episodes,
+		ETag:         payload.ETag,
+		LastModified: payload.LastModified,
+	}, nil
+}
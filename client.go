@@ -0,0 +1,252 @@
+package pcd
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultRequestTimeout = 30 * time.Second
+	defaultMaxRetries     = 3
+	defaultUserAgent      = "pcd/1.0"
+)
+
+// ClientOptions configures a Client.
+type ClientOptions struct {
+	// Timeout bounds a single HTTP request, including retries. Defaults to
+	// defaultRequestTimeout if zero.
+	Timeout time.Duration
+
+	// MaxIdleConns, MaxIdleConnsPerHost and IdleConnTimeout configure the
+	// underlying *http.Transport's connection pool. Zero means use
+	// net/http's own defaults for that field.
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+
+	// MaxRetries is the number of retries attempted for retryable failures
+	// on idempotent GET requests. Defaults to defaultMaxRetries if zero.
+	MaxRetries int
+
+	// UserAgent is sent with every request. Defaults to defaultUserAgent.
+	UserAgent string
+}
+
+// Client is a configured, reusable HTTP client for syncing podcasts and
+// downloading episodes. Unlike the one-shot http.Client Podcast.Sync and
+// Episode.Download used to create per call, it pools connections across
+// calls and retries idempotent GETs that fail transiently.
+type Client struct {
+	http       *http.Client
+	maxRetries int
+	userAgent  string
+}
+
+// defaultClient backs the package-level Podcast.Sync and Episode.Download
+// methods, kept for backward compatibility with callers that don't need a
+// custom Client.
+var defaultClient = NewClient(ClientOptions{})
+
+// NewClient builds a Client from opts, filling in sane defaults for any
+// zero-valued field.
+func NewClient(opts ClientOptions) *Client {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultRequestTimeout
+	}
+	maxRetries := opts.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = defaultMaxRetries
+	}
+	userAgent := opts.UserAgent
+	if userAgent == "" {
+		userAgent = defaultUserAgent
+	}
+
+	return &Client{
+		http: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				MaxIdleConns:        opts.MaxIdleConns,
+				MaxIdleConnsPerHost: opts.MaxIdleConnsPerHost,
+				IdleConnTimeout:     opts.IdleConnTimeout,
+			},
+		},
+		maxRetries: maxRetries,
+		userAgent:  userAgent,
+	}
+}
+
+// Sync fetches p's feed, updating p.Episodes and its cache validators
+// (p.ETag / p.LastModified). If the server reports the feed as unchanged
+// via a conditional request (HTTP 304), p.Episodes is left untouched and
+// parseEpisodes is skipped entirely.
+func (c *Client) Sync(ctx context.Context, p *Podcast) error {
+	req, err := http.NewRequest("GET", p.Feed, nil)
+	if err != nil {
+		log.Print(err)
+		return ErrCouldNotSync
+	}
+
+	if p.Username != "" {
+		req.SetBasicAuth(p.Username, p.Password)
+	}
+	req.Header.Set("Accept-Encoding", "gzip")
+	if p.ETag != "" {
+		req.Header.Set("If-None-Match", p.ETag)
+	}
+	if p.LastModified != "" {
+		req.Header.Set("If-Modified-Since", p.LastModified)
+	}
+
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		log.Print(err)
+		return ErrRequestFailed
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK: // NOOP
+	case http.StatusNotModified:
+		return nil
+	case http.StatusForbidden, http.StatusUnauthorized:
+		return ErrAccessDenied
+	case http.StatusNotFound:
+		return ErrFeedNotFound
+	case http.StatusInternalServerError:
+		return ErrRequestFailed
+	default:
+		return ErrRequestFailed
+	}
+
+	body := resp.Body
+	if resp.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			log.Print(err)
+			return ErrParserIssue
+		}
+		defer gz.Close()
+		body = gz
+	}
+
+	p.Episodes, err = parseEpisodes(body)
+	if err != nil {
+		log.Print(err)
+		return ErrParserIssue
+	}
+
+	p.ETag = resp.Header.Get("ETag")
+	p.LastModified = resp.Header.Get("Last-Modified")
+
+	if err := os.MkdirAll(p.Path, os.ModePerm); err != nil {
+		log.Print(err)
+		return ErrFilesystemError
+	}
+
+	if err := p.writeFeedCache(); err != nil {
+		log.Print(err)
+		return err
+	}
+
+	return nil
+}
+
+// Download downloads an episode to 'path'. The progress argument is
+// optional and will just mirror everything written into it (useful for
+// tracking the speed).
+func (c *Client) Download(ctx context.Context, e *Episode, path string, progress io.Writer) error {
+	req, err := http.NewRequest("GET", e.URL, nil)
+	if err != nil {
+		log.Printf("Could not download episode: %#v", err)
+		return ErrCouldNotDownload
+	}
+
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		log.Printf("Could not download episode: %#v", err)
+		return ErrCouldNotDownload
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("Could not download episode: unexpected status %d", resp.StatusCode)
+		return ErrCouldNotDownload
+	}
+
+	fpath := filepath.Join(path, filenameForURL(e.URL))
+	f, err := os.Create(fpath)
+	if err != nil {
+		log.Printf("Could not create file: %#v", err)
+		return ErrCouldNotDownload
+	}
+	defer f.Close()
+
+	var mw io.Writer = f
+	if progress != nil {
+		mw = io.MultiWriter(f, progress)
+	}
+	if _, err := io.Copy(mw, resp.Body); err != nil {
+		log.Printf("Could not write to file: %#v", err)
+		return ErrCouldNotDownload
+	}
+
+	return nil
+}
+
+// do executes req with the client's User-Agent, retrying idempotent GETs
+// on 5xx, 429 and temporary network errors with exponential backoff. 4xx
+// responses other than 429 are treated as terminal and returned as-is.
+func (c *Client) do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	req = req.WithContext(ctx)
+	req.Header.Set("User-Agent", c.userAgent)
+
+	var resp *http.Response
+	var err error
+
+	for attempt := 0; ; attempt++ {
+		resp, err = c.http.Do(req)
+		if attempt >= c.maxRetries || !shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-time.After(retryBackoff(attempt)):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if req.Method != http.MethodGet {
+		return false
+	}
+	if err != nil {
+		var netErr net.Error
+		return errors.As(err, &netErr) && netErr.Temporary()
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return resp.StatusCode >= 500
+}
+
+func retryBackoff(attempt int) time.Duration {
+	return time.Duration(math.Pow(2, float64(attempt))) * 100 * time.Millisecond
+}
@@ -1,13 +1,14 @@
 package pcd
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/gob"
 	"fmt"
 	"io"
 	"log"
-	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -29,6 +30,12 @@ type Podcast struct {
 
 	// List of episodes
 	Episodes []Episode
+
+	// Cache validators from the last successful sync, used to make
+	// conditional requests (If-None-Match / If-Modified-Since) so
+	// unchanged feeds don't have to be re-downloaded and reparsed.
+	ETag         string
+	LastModified string
 }
 
 type Episode struct {
@@ -51,48 +58,15 @@ var (
 	ErrCouldNotParseContent  = errors.New("Could not parse the content from the feed")
 )
 
+// Sync fetches the podcast's feed and refreshes p.Episodes, using a
+// one-shot default Client. See Client.Sync for the full behavior.
 func (p *Podcast) Sync() error {
-	client := &http.Client{}
-
-	req, err := http.NewRequest("GET", p.Feed, nil)
-	if err != nil {
-		log.Print(err)
-		return ErrCouldNotSync
-	}
-
-	if p.Username != "" {
-		req.SetBasicAuth(p.Username, p.Password)
-	}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Print(err)
-		return ErrRequestFailed
-	}
-	switch resp.StatusCode {
-	case http.StatusOK: // NOOP
-	case http.StatusForbidden, http.StatusUnauthorized:
-		return ErrAccessDenied
-	case http.StatusNotFound:
-		return ErrFeedNotFound
-	case http.StatusInternalServerError:
-		return ErrRequestFailed
-	default:
-		return ErrRequestFailed
-	}
-	defer resp.Body.Close()
-
-	p.Episodes, err = parseEpisodes(resp.Body)
-	if err != nil {
-		log.Print(err)
-		return ErrParserIssue
-	}
-
-	if err := os.MkdirAll(p.Path, os.ModePerm); err != nil {
-		log.Print(err)
-		return ErrFilesystemError
-	}
+	return defaultClient.Sync(context.Background(), p)
+}
 
+// writeFeedCache (over)writes the ".feed" cache file with the current,
+// versioned on-disk format (see encodeFeed).
+func (p *Podcast) writeFeedCache() error {
 	path := filepath.Join(p.Path, ".feed")
 	f, err := os.Create(path)
 	if err != nil {
@@ -101,15 +75,10 @@ func (p *Podcast) Sync() error {
 	}
 	defer f.Close()
 
-	blob, err := toGOB64(p.Episodes)
-	if err != nil {
+	if err := encodeFeed(f, p); err != nil {
 		log.Print(err)
 		return ErrEncodeError
 	}
-	if _, err := io.Copy(f, blob); err != nil {
-		log.Print(err)
-		return ErrFilesystemError
-	}
 
 	return nil
 }
@@ -123,12 +92,38 @@ func (p *Podcast) Load() error {
 	}
 	defer f.Close()
 
-	p.Episodes, err = fromGOB64(f)
+	br := bufio.NewReader(f)
+	header, err := br.Peek(len(feedMagic))
+	if err != nil && err != io.EOF {
+		log.Printf("Could not read feed file: %#v", err)
+		return ErrCouldNotReadFromCache
+	}
+
+	if bytes.Equal(header, feedMagic[:]) {
+		decoded, err := decodeFeed(br)
+		if err != nil {
+			log.Printf("Could not decode episodes: %#v", err)
+			return ErrCouldNotReadFromCache
+		}
+		p.Episodes = decoded.Episodes
+		p.ETag = decoded.ETag
+		p.LastModified = decoded.LastModified
+		return nil
+	}
+
+	// Legacy gob+base64 cache. Decode it with the old codec, then
+	// transparently rewrite the file in the new format so future loads
+	// (and future field additions) don't have to special-case it again.
+	p.Episodes, err = fromGOB64(br)
 	if err != nil {
 		log.Printf("Could not decode episodes: %#v", err)
 		return ErrCouldNotReadFromCache
 	}
 
+	if err := p.writeFeedCache(); err != nil {
+		log.Printf("Could not upgrade feed cache: %#v", err)
+	}
+
 	return nil
 }
 
@@ -164,44 +159,19 @@ func (p *Podcast) String() string {
 	return sb.String()
 }
 
-// Download downloads an episode in 'path'. The writer argument is optional
-// and will just mirror everything written into it (useful for tracking the speed)
+// Download downloads an episode in 'path', using a one-shot default
+// Client. The writer argument is optional and will just mirror everything
+// written into it (useful for tracking the speed). See Client.Download for
+// the full behavior.
 func (e *Episode) Download(path string, writer io.Writer) error {
-	res, err := http.Get(e.URL)
-	if err != nil {
-		log.Printf("Could not download episode: %#v", err)
-		return ErrCouldNotDownload
-	}
-	defer res.Body.Close()
-	if res.StatusCode != 200 {
-		log.Printf("Could not download episode: %#v", err)
-		return ErrCouldNotDownload
-	}
-
-	tokens := strings.Split(e.URL, "/")
-	filename := tokens[len(tokens)-1]
-
-	fpath := filepath.Join(path, filename)
-	f, err := os.Create(fpath)
-	if err != nil {
-		log.Printf("Could not create file: %#v", err)
-		return ErrCouldNotDownload
-	}
-	defer f.Close()
-
-	var mw io.Writer
-
-	if writer != nil {
-		mw = io.MultiWriter(f, writer)
-	} else {
-		mw = f
-	}
-	if _, err := io.Copy(mw, res.Body); err != nil {
-		log.Printf("Could not write to file: %#v", err)
-		return ErrCouldNotDownload
-	}
+	return defaultClient.Download(context.Background(), e, path, writer)
+}
 
-	return nil
+// filenameForURL derives the on-disk filename for an episode from the
+// last path segment of its URL.
+func filenameForURL(url string) string {
+	tokens := strings.Split(url, "/")
+	return tokens[len(tokens)-1]
 }
 
 func parseEpisodes(content io.Reader) ([]Episode, error) {
@@ -231,23 +201,8 @@ func parseEpisodes(content io.Reader) ([]Episode, error) {
 	return episodes, nil
 }
 
-func toGOB64(episodes []Episode) (io.Reader, error) {
-	b := bytes.Buffer{}
-
-	e := gob.NewEncoder(&b)
-	if err := e.Encode(episodes); err != nil {
-		return nil, err
-	}
-
-	dst := bytes.Buffer{}
-	encoder := base64.NewEncoder(base64.StdEncoding, &dst)
-	encoder.Write(b.Bytes())
-
-	defer encoder.Close()
-
-	return &dst, nil
-}
-
+// fromGOB64 decodes the legacy gob+base64 cache format. Kept around so
+// Load can transparently upgrade caches written before encodeFeed existed.
 func fromGOB64(content io.Reader) ([]Episode, error) {
 	var episodes []Episode
 